@@ -0,0 +1,88 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/brizzbuzz/opnix/internal/agent"
+	"github.com/brizzbuzz/opnix/internal/config"
+	"github.com/brizzbuzz/opnix/internal/errors"
+	"github.com/brizzbuzz/opnix/internal/onepass"
+)
+
+const defaultAgentSocketPath = "/run/opnix/io.systemd.credentials"
+
+type agentCommand struct {
+	fs                 *flag.FlagSet
+	configFile         string
+	socketPath         string
+	tokenFile          string
+	desktopIntegration string
+}
+
+func newAgentCommand() *agentCommand {
+	ac := &agentCommand{
+		fs: flag.NewFlagSet("agent", flag.ExitOnError),
+	}
+
+	ac.fs.StringVar(&ac.configFile, "config", "secrets.json", "Path to secrets configuration file")
+	ac.fs.StringVar(&ac.socketPath, "socket", defaultAgentSocketPath, "Path to the AF_UNIX socket to serve systemd credential requests on")
+	ac.fs.StringVar(&ac.tokenFile, "token-file", defaultTokenPath, "Path to file containing 1Password service account token")
+	ac.fs.StringVar(&ac.desktopIntegration, "desktop-integration", "", "Account name to use for 1Password desktop app integration. Overrides 'token-file' and uses the desktop app to authenticate.")
+
+	ac.fs.Usage = func() {
+		fmt.Fprintf(ac.fs.Output(), "Usage: opnix agent [options]\n\n")
+		fmt.Fprintf(ac.fs.Output(), "Run a socket-activated daemon serving secrets as systemd credentials\n\n")
+		fmt.Fprintf(ac.fs.Output(), "Options:\n")
+		ac.fs.PrintDefaults()
+	}
+
+	return ac
+}
+
+func (a *agentCommand) Name() string { return a.fs.Name() }
+
+func (a *agentCommand) Init(args []string) error {
+	return a.fs.Parse(args)
+}
+
+func (a *agentCommand) Run() error {
+	cfg, err := config.Load(a.configFile)
+	if err != nil {
+		return err
+	}
+
+	sandboxCfg := toSandboxConfig(cfg.Sandbox)
+
+	var client *onepass.Client
+	if a.desktopIntegration != "" {
+		client, err = onepass.NewClientWithDesktopIntegration(a.desktopIntegration, sandboxCfg)
+	} else {
+		client, err = onepass.NewClient(a.tokenFile, sandboxCfg)
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Initialized 1Password client successfully")
+
+	d, err := agent.NewDaemon(agent.Config{
+		ConfigFile: a.configFile,
+		SocketPath: a.socketPath,
+	}, client)
+	if err != nil {
+		return errors.WrapWithSuggestions(
+			err,
+			"Starting credential agent",
+			"opnix agent",
+			[]string{
+				"Ensure the parent directory of the socket path exists and is writable",
+				"If using systemd socket activation, confirm the unit's ListenStream matches -socket",
+			},
+		)
+	}
+
+	log.Printf("Serving systemd credentials on %s", a.socketPath)
+	return d.Serve()
+}
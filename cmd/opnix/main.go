@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/brizzbuzz/opnix/internal/onepass"
+)
+
+// command is implemented by every opnix subcommand.
+type command interface {
+	Name() string
+	Init(args []string) error
+	Run() error
+}
+
+func main() {
+	// A re-exec of opnix itself, used internally by the sandbox.backend=
+	// landlock path to apply a restriction to a disposable child rather than
+	// the long-lived opnix process (see onepass.WrapCommand). Must be
+	// checked before any flag parsing, since the remaining arguments aren't
+	// opnix's own CLI syntax.
+	if len(os.Args) > 1 && os.Args[1] == onepass.LandlockHelperArg {
+		if err := onepass.RunLandlockHelper(os.Args[2:]); err != nil {
+			log.Fatalf("landlock helper: %v", err)
+		}
+		return
+	}
+
+	commands := []command{
+		newSecretCommand(),
+		newAgentCommand(),
+		newWatchCommand(),
+	}
+
+	if len(os.Args) < 2 {
+		printUsage(commands)
+		os.Exit(1)
+	}
+
+	for _, cmd := range commands {
+		if cmd.Name() != os.Args[1] {
+			continue
+		}
+
+		if err := cmd.Init(os.Args[2:]); err != nil {
+			log.Fatalf("%s: %v", cmd.Name(), err)
+		}
+
+		if err := cmd.Run(); err != nil {
+			log.Fatalf("%s: %v", cmd.Name(), err)
+		}
+
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "Unknown command: %s\n\n", os.Args[1])
+	printUsage(commands)
+	os.Exit(1)
+}
+
+func printUsage(commands []command) {
+	fmt.Fprintf(os.Stderr, "Usage: opnix <command> [options]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %s\n", cmd.Name())
+	}
+}
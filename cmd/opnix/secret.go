@@ -14,8 +14,25 @@ import (
 	"github.com/brizzbuzz/opnix/internal/validation"
 )
 
+// capChownSuggestions are shown when a secret requests an owner/group other
+// than the current user's but the process lacks CAP_CHOWN to apply it.
+var capChownSuggestions = []string{
+	"Run opnix under systemd with AmbientCapabilities=CAP_CHOWN",
+	"Run opnix as root",
+	"Drop the owner/group overrides from secrets.json entries that don't need them",
+}
+
 const defaultTokenPath = "/etc/opnix-token"
 
+// toSandboxConfig adapts the parsed secrets.json "sandbox" block to the
+// onepass.SandboxConfig its client constructors expect.
+func toSandboxConfig(sandbox config.Sandbox) onepass.SandboxConfig {
+	return onepass.SandboxConfig{
+		Enable:  sandbox.Enable,
+		Backend: onepass.SandboxBackend(sandbox.Backend),
+	}
+}
+
 type secretCommand struct {
 	fs                 *flag.FlagSet
 	configFile         string
@@ -65,12 +82,19 @@ func (s *secretCommand) Run() error {
 
 	log.Printf("Loaded configuration with %d secrets", len(cfg.Secrets))
 
+	if err := s.validateSecretPermissions(cfg); err != nil {
+		return err
+	}
+
+	s.validateSandboxPrerequisites(cfg)
+
 	// Initialize 1Password client with validation
+	sandboxCfg := toSandboxConfig(cfg.Sandbox)
 	var client *onepass.Client
 	if s.desktopIntegration != "" {
-		client, err = onepass.NewClientWithDesktopIntegration(s.desktopIntegration)
+		client, err = onepass.NewClientWithDesktopIntegration(s.desktopIntegration, sandboxCfg)
 	} else {
-		client, err = onepass.NewClient(s.tokenFile)
+		client, err = onepass.NewClient(s.tokenFile, sandboxCfg)
 	}
 	if err != nil {
 		// Error already has context from onepass.NewClient
@@ -155,6 +179,77 @@ func (s *secretCommand) validatePrerequisites() error {
 	return nil
 }
 
+// validateSecretPermissions fails fast if any secret requests an owner or
+// group other than the current user's while the process lacks CAP_CHOWN to
+// apply it. Without this, a misconfigured secret would only surface the
+// chown failure midway through processing, after earlier secrets were
+// already written.
+func (s *secretCommand) validateSecretPermissions(cfg *config.Config) error {
+	return validateSecretOwnership(cfg)
+}
+
+// validateSecretOwnership is the shared CAP_CHOWN pre-flight used by both
+// secretCommand and watchCommand, since both write secrets to disk and both
+// need to fail before processing rather than mid-write.
+func validateSecretOwnership(cfg *config.Config) error {
+	needsChown := false
+	for _, secret := range cfg.Secrets {
+		requires, err := secrets.RequiresPrivilegedChown(secrets.OwnerSpec{
+			Owner: secret.Owner,
+			Group: secret.Group,
+			Mode:  secret.Mode,
+		})
+		if err != nil {
+			return errors.WrapWithSuggestions(
+				err,
+				"Validating secret ownership",
+				secret.Name,
+				[]string{"Check that the configured owner/group names exist on this system"},
+			)
+		}
+		if requires {
+			needsChown = true
+			break
+		}
+	}
+
+	if !needsChown {
+		return nil
+	}
+
+	hasCapability, err := validation.HasChownCapability()
+	if err != nil {
+		return errors.WrapWithSuggestions(err, "Checking CAP_CHOWN capability", "secret ownership", capChownSuggestions)
+	}
+	if !hasCapability {
+		return errors.WrapWithSuggestions(
+			fmt.Errorf("one or more secrets request a different owner/group"),
+			"Checking CAP_CHOWN capability",
+			"secret ownership",
+			capChownSuggestions,
+		)
+	}
+
+	return nil
+}
+
+// validateSandboxPrerequisites downgrades (rather than failing) when
+// sandbox.backend is set to "landlock" but the running kernel doesn't
+// support it, since a missing Landlock ABI shouldn't block secret retrieval
+// entirely - it just means the op subprocess falls back to bwrap instead.
+// cfg is mutated in place so the fallback is reflected in the SandboxConfig
+// built from it afterwards.
+func (s *secretCommand) validateSandboxPrerequisites(cfg *config.Config) {
+	if !cfg.Sandbox.Enable || onepass.SandboxBackend(cfg.Sandbox.Backend) != onepass.SandboxBackendLandlock {
+		return
+	}
+	if !onepass.LandlockSupported() {
+		fmt.Fprintf(os.Stderr, "WARNING: sandbox.backend=landlock requested but this kernel has no Landlock support\n")
+		fmt.Fprintf(os.Stderr, "INFO: Falling back to sandbox.backend=bwrap for the op subprocess\n")
+		cfg.Sandbox.Backend = string(onepass.SandboxBackendBubblewrap)
+	}
+}
+
 // checkOutputDirectory ensures the output directory is accessible
 func (s *secretCommand) checkOutputDirectory() error {
 	// Try to create the directory if it doesn't exist
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/brizzbuzz/opnix/internal/config"
+	"github.com/brizzbuzz/opnix/internal/errors"
+	"github.com/brizzbuzz/opnix/internal/onepass"
+	"github.com/brizzbuzz/opnix/internal/secrets"
+	"github.com/brizzbuzz/opnix/internal/systemd"
+)
+
+const defaultWatchInterval = 5 * time.Minute
+
+type watchCommand struct {
+	fs                 *flag.FlagSet
+	configFile         string
+	outputDir          string
+	tokenFile          string
+	desktopIntegration string
+	interval           time.Duration
+	controlSocket      string
+}
+
+func newWatchCommand() *watchCommand {
+	wc := &watchCommand{
+		fs: flag.NewFlagSet("watch", flag.ExitOnError),
+	}
+
+	wc.fs.StringVar(&wc.configFile, "config", "secrets.json", "Path to secrets configuration file")
+	wc.fs.StringVar(&wc.outputDir, "output", "secrets", "Directory to store retrieved secrets")
+	wc.fs.StringVar(&wc.tokenFile, "token-file", defaultTokenPath, "Path to file containing 1Password service account token")
+	wc.fs.StringVar(&wc.desktopIntegration, "desktop-integration", "", "Account name to use for 1Password desktop app integration. Overrides 'token-file' and uses the desktop app to authenticate.")
+	wc.fs.DurationVar(&wc.interval, "interval", defaultWatchInterval, "How often to re-fetch and compare every configured secret")
+	wc.fs.StringVar(&wc.controlSocket, "control-socket", "", "Optional AF_UNIX socket; writing a secret name to it triggers an immediate refresh of just that entry")
+
+	wc.fs.Usage = func() {
+		fmt.Fprintf(wc.fs.Output(), "Usage: opnix watch [options]\n\n")
+		fmt.Fprintf(wc.fs.Output(), "Keep secrets up to date by periodically re-fetching and reloading dependent units\n\n")
+		fmt.Fprintf(wc.fs.Output(), "Options:\n")
+		wc.fs.PrintDefaults()
+	}
+
+	return wc
+}
+
+func (w *watchCommand) Name() string { return w.fs.Name() }
+
+func (w *watchCommand) Init(args []string) error {
+	return w.fs.Parse(args)
+}
+
+func (w *watchCommand) Run() error {
+	cfg, err := config.Load(w.configFile)
+	if err != nil {
+		return err
+	}
+
+	client, err := w.newClient(cfg.Sandbox)
+	if err != nil {
+		return err
+	}
+
+	processor := secrets.NewProcessor(client, w.outputDir)
+
+	refresh := make(chan string, 8)
+
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+
+	if w.controlSocket != "" {
+		if err := w.serveControlSocket(refresh); err != nil {
+			return errors.WrapWithSuggestions(
+				err,
+				"Starting watch control socket",
+				"opnix watch",
+				[]string{
+					"Ensure the parent directory of -control-socket exists and is writable",
+					"Check that no other process already owns that socket path",
+				},
+			)
+		}
+	}
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	log.Printf("Watching %d secrets every %s", len(cfg.Secrets), w.interval)
+
+	for {
+		var only string
+		select {
+		case <-ticker.C:
+			only = ""
+		case <-sighup:
+			log.Printf("Received SIGHUP, refreshing all secrets")
+			only = ""
+		case name := <-refresh:
+			only = name
+		}
+
+		if err := w.refreshOnce(processor, only); err != nil {
+			log.Printf("watch: refresh failed: %v", err)
+		}
+	}
+}
+
+func (w *watchCommand) newClient(sandbox config.Sandbox) (*onepass.Client, error) {
+	sandboxCfg := toSandboxConfig(sandbox)
+	if w.desktopIntegration != "" {
+		return onepass.NewClientWithDesktopIntegration(w.desktopIntegration, sandboxCfg)
+	}
+	return onepass.NewClient(w.tokenFile, sandboxCfg)
+}
+
+// refreshOnce re-fetches every configured secret (or, when only is
+// non-empty, just that one), rewrites any whose contents changed, and, if
+// any did, restarts the services configured under systemdIntegration.
+func (w *watchCommand) refreshOnce(processor *secrets.Processor, only string) error {
+	cfg, err := config.Load(w.configFile)
+	if err != nil {
+		return err
+	}
+
+	if only != "" {
+		filtered := cfg.Secrets[:0]
+		for _, secret := range cfg.Secrets {
+			if secret.Name == only {
+				filtered = append(filtered, secret)
+			}
+		}
+		cfg.Secrets = filtered
+	}
+
+	if err := validateSecretOwnership(cfg); err != nil {
+		return err
+	}
+
+	result, err := processor.Process(cfg)
+	if err != nil {
+		return err
+	}
+
+	if result.ProcessedCount == 0 {
+		return nil
+	}
+
+	log.Printf("Refreshed %d secret(s)", result.ProcessedCount)
+
+	if !cfg.SystemdIntegration.Enable {
+		return nil
+	}
+
+	systemdManager, err := systemd.NewManager(cfg.SystemdIntegration)
+	if err != nil {
+		return err
+	}
+
+	return systemdManager.ProcessSecretChanges(cfg.Secrets, result.SecretPaths)
+}
+
+// serveControlSocket listens on w.controlSocket and forwards each connection's
+// payload (a secret name) onto refresh, for on-demand, single-secret
+// refreshes without waiting for the next poll interval.
+func (w *watchCommand) serveControlSocket(refresh chan<- string) error {
+	_ = os.Remove(w.controlSocket)
+
+	addr, err := net.ResolveUnixAddr("unix", w.controlSocket)
+	if err != nil {
+		return fmt.Errorf("resolving control socket address: %w", err)
+	}
+
+	ln, err := net.ListenUnix("unix", addr)
+	if err != nil {
+		return fmt.Errorf("listening on control socket: %w", err)
+	}
+
+	go func() {
+		for {
+			conn, err := ln.AcceptUnix()
+			if err != nil {
+				log.Printf("watch: control socket accept failed: %v", err)
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+				buf := make([]byte, 256)
+				n, err := conn.Read(buf)
+				if err != nil {
+					return
+				}
+				refresh <- strings.TrimSpace(string(buf[:n]))
+			}()
+		}
+	}()
+
+	return nil
+}
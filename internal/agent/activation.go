@@ -0,0 +1,54 @@
+package agent
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+)
+
+// listenFDsStart is the first inherited file descriptor systemd passes to a
+// socket-activated process, per sd_listen_fds(3).
+const listenFDsStart = 3
+
+// socketFromActivation returns the listener systemd already bound for us,
+// per LISTEN_PID/LISTEN_FDS (sd_listen_fds(3)), or nil if this process
+// wasn't socket-activated. Binding our own socket instead - as NewDaemon
+// used to unconditionally do - would race with, and discard, the one
+// systemd already created and handed the triggering connection to.
+func socketFromActivation() (*net.UnixListener, error) {
+	pidStr := os.Getenv("LISTEN_PID")
+	if pidStr == "" {
+		return nil, nil
+	}
+
+	pid, err := strconv.Atoi(pidStr)
+	if err != nil || pid != os.Getpid() {
+		// Not meant for us (e.g. inherited across an unrelated exec).
+		return nil, nil
+	}
+
+	fdsStr := os.Getenv("LISTEN_FDS")
+	if fdsStr == "" {
+		return nil, nil
+	}
+
+	fds, err := strconv.Atoi(fdsStr)
+	if err != nil || fds < 1 {
+		return nil, fmt.Errorf("parsing LISTEN_FDS=%q: %w", fdsStr, err)
+	}
+
+	file := os.NewFile(uintptr(listenFDsStart), "opnix-agent-socket")
+	ln, err := net.FileListener(file)
+	if err != nil {
+		return nil, fmt.Errorf("adopting socket-activated fd %d: %w", listenFDsStart, err)
+	}
+
+	unixLn, ok := ln.(*net.UnixListener)
+	if !ok {
+		ln.Close()
+		return nil, fmt.Errorf("socket-activated fd %d is not an AF_UNIX listener", listenFDsStart)
+	}
+
+	return unixLn, nil
+}
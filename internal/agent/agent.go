@@ -0,0 +1,177 @@
+// Package agent implements a socket-activated daemon that serves secrets
+// from 1Password as systemd credentials, following the io.systemd.Credentials
+// AF_UNIX protocol consumed by LoadCredential= and ImportCredential=.
+package agent
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/brizzbuzz/opnix/internal/config"
+	"github.com/brizzbuzz/opnix/internal/onepass"
+)
+
+// Config controls where the daemon reads its secret mappings from and where
+// it listens for credential requests.
+type Config struct {
+	ConfigFile string
+	SocketPath string
+}
+
+// Daemon is a long-lived process that accepts one connection per credential
+// request, resolves the requested name to a 1Password reference via the
+// configured secrets.json, and streams the raw secret bytes back.
+type Daemon struct {
+	cfg      Config
+	client   *onepass.Client
+	listener *net.UnixListener
+	queue    *requestQueue
+}
+
+// NewDaemon adopts the AF_UNIX socket systemd activated us on, or - when run
+// standalone, e.g. under a plain systemd.service with no matching socket
+// unit - binds cfg.SocketPath itself, creating its parent directory if
+// necessary.
+func NewDaemon(cfg Config, client *onepass.Client) (*Daemon, error) {
+	ln, err := socketFromActivation()
+	if err != nil {
+		return nil, err
+	}
+
+	if ln == nil {
+		if err := os.MkdirAll(filepath.Dir(cfg.SocketPath), 0750); err != nil {
+			return nil, fmt.Errorf("creating socket directory: %w", err)
+		}
+
+		_ = os.Remove(cfg.SocketPath)
+
+		addr, err := net.ResolveUnixAddr("unix", cfg.SocketPath)
+		if err != nil {
+			return nil, fmt.Errorf("resolving socket address %q: %w", cfg.SocketPath, err)
+		}
+
+		ln, err = net.ListenUnix("unix", addr)
+		if err != nil {
+			return nil, fmt.Errorf("listening on %q: %w", cfg.SocketPath, err)
+		}
+	}
+
+	queue, err := newRequestQueue()
+	if err != nil {
+		ln.Close()
+		return nil, err
+	}
+
+	return &Daemon{cfg: cfg, client: client, listener: ln, queue: queue}, nil
+}
+
+// Serve accepts credential connections forever, handing each off to a
+// non-blocking lookup so a slow 1Password fetch for one unit never stalls
+// requests from another. It returns only when epoll_wait fails; closing the
+// listener stops new connections from being accepted but does not make
+// Serve return.
+func (d *Daemon) Serve() error {
+	defer d.queue.close()
+
+	go d.acceptLoop()
+
+	for {
+		if err := d.queue.wait(d.deliver); err != nil {
+			return err
+		}
+	}
+}
+
+func (d *Daemon) acceptLoop() {
+	for {
+		conn, err := d.listener.AcceptUnix()
+		if err != nil {
+			log.Printf("agent: accept failed, stopping accept loop: %v", err)
+			return
+		}
+
+		go d.handleConn(conn)
+	}
+}
+
+func (d *Daemon) handleConn(conn *net.UnixConn) {
+	// The credential address is sent as the first line on the connection,
+	// matching io.systemd.Credentials' request framing.
+	buf := make([]byte, 4096)
+	n, err := conn.Read(buf)
+	if err != nil {
+		log.Printf("agent: reading credential address: %v", err)
+		conn.Close()
+		return
+	}
+
+	addr, err := parseCredentialAddress(string(buf[:n]))
+	if err != nil {
+		log.Printf("agent: %v", err)
+		conn.Close()
+		return
+	}
+
+	file, err := conn.File()
+	if err != nil {
+		log.Printf("agent: dup'ing client connection: %v", err)
+		conn.Close()
+		return
+	}
+	conn.Close()
+
+	// conn.File() returns an *os.File wrapping a dup'd fd, finalizer and
+	// all. We only keep the raw fd number (to hand off to epoll via the
+	// queue), so the *os.File value itself is about to become unreachable
+	// - and os.File's GC finalizer would then close this fd out from under
+	// us at some arbitrary later point, possibly after the number has been
+	// reused by something unrelated. Clear the finalizer first so only our
+	// own close (in deliver) ever touches it.
+	runtime.SetFinalizer(file, nil)
+	clientFd := int(file.Fd())
+	if err := d.queue.enqueue(addr, clientFd, func() ([]byte, error) {
+		return d.lookup(addr)
+	}); err != nil {
+		log.Printf("agent: enqueueing %s/%s: %v", addr.Unit, addr.CredentialName, err)
+		file.Close()
+	}
+}
+
+// lookup resolves a credential name to its configured 1Password reference
+// and fetches the current value. It re-reads secrets.json on every call so
+// newly added mappings are picked up without a daemon restart.
+func (d *Daemon) lookup(addr credentialAddress) ([]byte, error) {
+	cfg, err := config.Load(d.cfg.ConfigFile)
+	if err != nil {
+		return nil, fmt.Errorf("loading %s: %w", d.cfg.ConfigFile, err)
+	}
+
+	for _, secret := range cfg.Secrets {
+		if secret.Name == addr.CredentialName {
+			return d.client.FetchSecret(secret.Reference)
+		}
+	}
+
+	return nil, fmt.Errorf("no secret named %q configured for unit %q", addr.CredentialName, addr.Unit)
+}
+
+func (d *Daemon) deliver(req *pendingRequest) {
+	req.mu.Lock()
+	result, err := req.result, req.err
+	req.mu.Unlock()
+
+	defer unixClose(req.client)
+
+	if err != nil {
+		log.Printf("agent: resolving %s/%s: %v", req.addr.Unit, req.addr.CredentialName, err)
+		return
+	}
+
+	if _, err := writeAll(req.client, result); err != nil {
+		log.Printf("agent: writing credential %s/%s to client: %v", req.addr.Unit, req.addr.CredentialName, err)
+	}
+}
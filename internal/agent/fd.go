@@ -0,0 +1,20 @@
+package agent
+
+import "syscall"
+
+// writeAll writes b to the raw file descriptor fd, retrying on short writes.
+func writeAll(fd int, b []byte) (int, error) {
+	total := 0
+	for total < len(b) {
+		n, err := syscall.Write(fd, b[total:])
+		if err != nil {
+			return total, err
+		}
+		total += n
+	}
+	return total, nil
+}
+
+func unixClose(fd int) {
+	_ = syscall.Close(fd)
+}
@@ -0,0 +1,34 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+)
+
+// credentialAddress is a parsed systemd credential request, matching the
+// "unit/<service>/<credential-name>" convention used by LoadCredential= and
+// ImportCredential= when talking to a socket-activated AF_UNIX credential
+// provider (see systemd.io.systemd.Credentials(5)).
+type credentialAddress struct {
+	Unit           string
+	CredentialName string
+}
+
+// parseCredentialAddress parses the peer-supplied path component of a
+// credential request, e.g. "unit/nginx.service/db-password".
+func parseCredentialAddress(addr string) (credentialAddress, error) {
+	// systemd's io.systemd.Credentials protocol terminates the request with
+	// a trailing newline; trim it (and any other surrounding whitespace)
+	// before splitting, or the last path component never matches a
+	// configured secret name.
+	addr = strings.TrimSpace(addr)
+	parts := strings.Split(strings.Trim(addr, "/"), "/")
+	if len(parts) != 3 || parts[0] != "unit" || parts[1] == "" || parts[2] == "" {
+		return credentialAddress{}, fmt.Errorf("malformed credential address %q: want unit/<service>/<credential-name>", addr)
+	}
+
+	return credentialAddress{
+		Unit:           parts[1],
+		CredentialName: parts[2],
+	}, nil
+}
@@ -0,0 +1,56 @@
+package agent
+
+import "testing"
+
+func TestParseCredentialAddress(t *testing.T) {
+	cases := []struct {
+		name    string
+		addr    string
+		want    credentialAddress
+		wantErr bool
+	}{
+		{
+			name: "well formed",
+			addr: "unit/nginx.service/db-password",
+			want: credentialAddress{Unit: "nginx.service", CredentialName: "db-password"},
+		},
+		{
+			name: "trailing newline from systemd framing",
+			addr: "unit/nginx.service/db-password\n",
+			want: credentialAddress{Unit: "nginx.service", CredentialName: "db-password"},
+		},
+		{
+			name:    "missing credential name",
+			addr:    "unit/nginx.service/",
+			wantErr: true,
+		},
+		{
+			name:    "missing unit prefix",
+			addr:    "nginx.service/db-password",
+			wantErr: true,
+		},
+		{
+			name:    "too many segments",
+			addr:    "unit/nginx.service/sub/db-password",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseCredentialAddress(tc.addr)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("got %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
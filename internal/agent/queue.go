@@ -0,0 +1,132 @@
+package agent
+
+import (
+	"fmt"
+	"sync"
+	"syscall"
+)
+
+// sysEventfd2 is EVENTFD2's syscall number on amd64 Linux. The standard
+// library's syscall package exposes epoll_create1/epoll_ctl/epoll_wait
+// directly but not eventfd2, so it's invoked via the raw syscall here
+// rather than pulling in golang.org/x/sys/unix for one call.
+const sysEventfd2 = 290
+
+func eventfd(flags int) (int, error) {
+	fd, _, errno := syscall.Syscall(sysEventfd2, 0, uintptr(flags), 0)
+	if errno != 0 {
+		return -1, errno
+	}
+	return int(fd), nil
+}
+
+const (
+	efdCloexec  = 0x80000
+	efdNonblock = 0x800
+)
+
+// pendingRequest is a credential fetch that hasn't resolved yet. It carries
+// an eventfd that enqueue's fetch goroutine signals once the secret is available (or the
+// fetch failed), so the epoll loop only wakes up when there's something to
+// write back to the client.
+type pendingRequest struct {
+	addr   credentialAddress
+	client int // connection fd
+	notify int // eventfd, readable once result is set
+
+	mu     sync.Mutex
+	result []byte
+	err    error
+}
+
+// requestQueue multiplexes in-flight credential lookups over a single epoll
+// instance so a slow 1Password fetch never blocks other connections from
+// being accepted or served.
+type requestQueue struct {
+	epfd int
+
+	mu      sync.Mutex
+	pending map[int]*pendingRequest // keyed by notify fd
+}
+
+func newRequestQueue() (*requestQueue, error) {
+	epfd, err := syscall.EpollCreate1(syscall.EPOLL_CLOEXEC)
+	if err != nil {
+		return nil, fmt.Errorf("creating epoll instance: %w", err)
+	}
+
+	return &requestQueue{
+		epfd:    epfd,
+		pending: make(map[int]*pendingRequest),
+	}, nil
+}
+
+// enqueue registers a request's client connection for deferred completion
+// and kicks off the lookup in the background via fetch.
+func (q *requestQueue) enqueue(addr credentialAddress, clientFd int, fetch func() ([]byte, error)) error {
+	notifyFd, err := eventfd(efdCloexec | efdNonblock)
+	if err != nil {
+		return fmt.Errorf("creating eventfd: %w", err)
+	}
+
+	req := &pendingRequest{addr: addr, client: clientFd, notify: notifyFd}
+
+	q.mu.Lock()
+	q.pending[notifyFd] = req
+	q.mu.Unlock()
+
+	if err := syscall.EpollCtl(q.epfd, syscall.EPOLL_CTL_ADD, notifyFd, &syscall.EpollEvent{
+		Events: syscall.EPOLLIN,
+		Fd:     int32(notifyFd),
+	}); err != nil {
+		return fmt.Errorf("registering eventfd with epoll: %w", err)
+	}
+
+	go func() {
+		result, ferr := fetch()
+		req.mu.Lock()
+		req.result, req.err = result, ferr
+		req.mu.Unlock()
+		_, _ = writeAll(notifyFd, []byte{1, 0, 0, 0, 0, 0, 0, 0})
+	}()
+
+	return nil
+}
+
+// wait blocks until at least one pending request has resolved, then invokes
+// onReady for each and removes it from the queue.
+func (q *requestQueue) wait(onReady func(req *pendingRequest)) error {
+	events := make([]syscall.EpollEvent, 16)
+	n, err := syscall.EpollWait(q.epfd, events, -1)
+	if err != nil {
+		if err == syscall.EINTR {
+			return nil
+		}
+		return fmt.Errorf("epoll_wait: %w", err)
+	}
+
+	for i := 0; i < n; i++ {
+		fd := int(events[i].Fd)
+
+		q.mu.Lock()
+		req, ok := q.pending[fd]
+		if ok {
+			delete(q.pending, fd)
+		}
+		q.mu.Unlock()
+
+		if !ok {
+			continue
+		}
+
+		_ = syscall.EpollCtl(q.epfd, syscall.EPOLL_CTL_DEL, fd, nil)
+		_ = syscall.Close(fd)
+		onReady(req)
+	}
+
+	return nil
+}
+
+func (q *requestQueue) close() error {
+	return syscall.Close(q.epfd)
+}
@@ -0,0 +1,92 @@
+// Package config loads and validates opnix's secrets.json configuration.
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Secret is a single entry in the top-level "secrets" array of
+// secrets.json.
+type Secret struct {
+	Name      string `json:"name"`
+	Reference string `json:"reference"`
+	Path      string `json:"path,omitempty"`
+
+	// Owner, Group and Mode optionally override the default file
+	// permissions a secret is written with. Mode is an octal string
+	// (e.g. "0440"); Owner/Group are resolved via os/user at write time.
+	Owner string `json:"owner,omitempty"`
+	Group string `json:"group,omitempty"`
+	Mode  string `json:"mode,omitempty"`
+
+	// Type selects how this entry's value is produced. The zero value
+	// fetches Reference directly; "template" instead renders Source
+	// against Variables (each resolved the same way Reference is).
+	Type string `json:"type,omitempty"`
+
+	// Source is the template body (or a path to a file containing it)
+	// for Type: "template" entries.
+	Source string `json:"source,omitempty"`
+
+	// Variables maps template variable names to 1Password references,
+	// for Type: "template" entries.
+	Variables map[string]string `json:"variables,omitempty"`
+}
+
+// SystemdIntegration controls whether, and which, systemd units are
+// reloaded/restarted after their secrets change.
+type SystemdIntegration struct {
+	Enable   bool     `json:"enable"`
+	Services []string `json:"services,omitempty"`
+}
+
+// Sandbox is the opt-in "sandbox" block of secrets.json, confining the op
+// subprocess used to fetch secrets. See internal/onepass.SandboxConfig.
+type Sandbox struct {
+	Enable  bool   `json:"enable"`
+	Backend string `json:"backend,omitempty"`
+}
+
+// Config is the parsed form of secrets.json.
+type Config struct {
+	Secrets            []Secret           `json:"secrets"`
+	SystemdIntegration SystemdIntegration `json:"systemdIntegration,omitempty"`
+	Sandbox            Sandbox            `json:"sandbox,omitempty"`
+}
+
+// Load reads and parses the secrets configuration file at path.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	for i, secret := range cfg.Secrets {
+		if secret.Name == "" {
+			return nil, fmt.Errorf("%s: secrets[%d] is missing required field \"name\"", path, i)
+		}
+
+		if secret.Type == "template" {
+			if secret.Source == "" {
+				return nil, fmt.Errorf("%s: secret %q is missing required field \"source\"", path, secret.Name)
+			}
+			if len(secret.Variables) == 0 {
+				return nil, fmt.Errorf("%s: secret %q is missing required field \"variables\"", path, secret.Name)
+			}
+			continue
+		}
+
+		if secret.Reference == "" {
+			return nil, fmt.Errorf("%s: secret %q is missing required field \"reference\"", path, secret.Name)
+		}
+	}
+
+	return &cfg, nil
+}
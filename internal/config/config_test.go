@@ -0,0 +1,53 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "secrets.json")
+	if err := os.WriteFile(path, []byte(body), 0600); err != nil {
+		t.Fatalf("writing fixture config: %v", err)
+	}
+	return path
+}
+
+func TestLoadTemplateSecretWithoutReference(t *testing.T) {
+	path := writeConfig(t, `{"secrets":[{"name":"pgpass","type":"template","source":"x","variables":{"user":"op://v/i/u"}}]}`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.Secrets) != 1 || cfg.Secrets[0].Name != "pgpass" {
+		t.Fatalf("unexpected secrets: %+v", cfg.Secrets)
+	}
+}
+
+func TestLoadTemplateSecretMissingSource(t *testing.T) {
+	path := writeConfig(t, `{"secrets":[{"name":"pgpass","type":"template","variables":{"user":"op://v/i/u"}}]}`)
+
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "\"source\"") {
+		t.Fatalf("expected missing source error, got %v", err)
+	}
+}
+
+func TestLoadTemplateSecretMissingVariables(t *testing.T) {
+	path := writeConfig(t, `{"secrets":[{"name":"pgpass","type":"template","source":"x"}]}`)
+
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "\"variables\"") {
+		t.Fatalf("expected missing variables error, got %v", err)
+	}
+}
+
+func TestLoadNonTemplateSecretRequiresReference(t *testing.T) {
+	path := writeConfig(t, `{"secrets":[{"name":"pgpass"}]}`)
+
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "\"reference\"") {
+		t.Fatalf("expected missing reference error, got %v", err)
+	}
+}
@@ -0,0 +1,46 @@
+// Package errors wraps operational errors with the operation, context and
+// remediation suggestions opnix surfaces to the operator on the CLI.
+package errors
+
+import "fmt"
+
+// OpError is an error enriched with what opnix was doing, what it was
+// doing it to, and actionable suggestions for resolving it.
+type OpError struct {
+	Operation   string
+	Context     string
+	Suggestions []string
+	Err         error
+}
+
+func (e *OpError) Error() string {
+	msg := fmt.Sprintf("%s: %v", e.Operation, e.Err)
+	if e.Context != "" {
+		msg = fmt.Sprintf("%s (%s): %v", e.Operation, e.Context, e.Err)
+	}
+	for _, s := range e.Suggestions {
+		msg += fmt.Sprintf("\n  - %s", s)
+	}
+	return msg
+}
+
+func (e *OpError) Unwrap() error { return e.Err }
+
+// WrapWithSuggestions attaches operation/context metadata and remediation
+// suggestions to err, for display on the CLI.
+func WrapWithSuggestions(err error, operation, context string, suggestions []string) error {
+	if err == nil {
+		return nil
+	}
+	return &OpError{Operation: operation, Context: context, Err: err, Suggestions: suggestions}
+}
+
+// FileOperationError reports a failure to read, write or stat path during
+// operation, with message as a human-readable summary.
+func FileOperationError(operation, path, message string, err error) error {
+	return &OpError{
+		Operation: operation,
+		Context:   path,
+		Err:       fmt.Errorf("%s: %w", message, err),
+	}
+}
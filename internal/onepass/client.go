@@ -0,0 +1,69 @@
+// Package onepass execs the 1Password CLI ("op") to fetch secret values.
+package onepass
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// Client fetches secret values from 1Password via the op CLI.
+type Client struct {
+	opBinary           string
+	tokenFile          string
+	desktopIntegration string
+	sandbox            SandboxConfig
+}
+
+// NewClient authenticates via a service account token file.
+func NewClient(tokenFile string, sandbox SandboxConfig) (*Client, error) {
+	opBinary, err := exec.LookPath("op")
+	if err != nil {
+		return nil, fmt.Errorf("locating op CLI on PATH: %w", err)
+	}
+
+	if _, err := os.Stat(tokenFile); err != nil {
+		return nil, fmt.Errorf("reading token file %s: %w", tokenFile, err)
+	}
+
+	return &Client{opBinary: opBinary, tokenFile: tokenFile, sandbox: sandbox}, nil
+}
+
+// NewClientWithDesktopIntegration authenticates via the 1Password desktop
+// app's CLI integration for the given account, instead of a token file.
+func NewClientWithDesktopIntegration(account string, sandbox SandboxConfig) (*Client, error) {
+	opBinary, err := exec.LookPath("op")
+	if err != nil {
+		return nil, fmt.Errorf("locating op CLI on PATH: %w", err)
+	}
+
+	return &Client{opBinary: opBinary, desktopIntegration: account, sandbox: sandbox}, nil
+}
+
+// FetchSecret resolves a 1Password secret reference (e.g.
+// "op://vault/item/field") to its current value.
+func (c *Client) FetchSecret(reference string) ([]byte, error) {
+	cmd := exec.Command(c.opBinary, "read", "-n", reference)
+	cmd.Env = os.Environ()
+
+	if c.desktopIntegration != "" {
+		cmd.Env = append(cmd.Env, "OP_ACCOUNT="+c.desktopIntegration)
+	} else {
+		cmd.Env = append(cmd.Env, "OP_SERVICE_ACCOUNT_TOKEN_FILE="+c.tokenFile)
+	}
+
+	if err := WrapCommand(cmd, c.sandbox, c.opBinary, c.tokenFile); err != nil {
+		return nil, fmt.Errorf("sandboxing op subprocess: %w", err)
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("op read %s: %w: %s", reference, err, stderr.String())
+	}
+
+	return bytes.TrimRight(out, "\n"), nil
+}
@@ -0,0 +1,196 @@
+package onepass
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// SandboxBackend selects how the op subprocess is confined.
+type SandboxBackend string
+
+const (
+	SandboxBackendBubblewrap SandboxBackend = "bwrap"
+	SandboxBackendLandlock   SandboxBackend = "landlock"
+)
+
+// SandboxConfig is the opt-in "sandbox" block of the opnix config, confining
+// what the op subprocess can see beyond its own binary, the token file, and
+// network egress to 1Password's API.
+type SandboxConfig struct {
+	Enable  bool
+	Backend SandboxBackend
+}
+
+// landlockHelperArg marks a re-exec of the opnix binary itself as the
+// landlock sandboxing helper (see RunLandlockHelper) rather than a normal
+// CLI invocation. main() must check for this as its very first action,
+// before any flag parsing.
+const landlockHelperArg = "__opnix_landlock_exec__"
+
+// LandlockHelperArg is the first argument main() must check for to detect
+// a landlock helper re-exec; see RunLandlockHelper.
+const LandlockHelperArg = landlockHelperArg
+
+// WrapCommand adjusts cmd in place so that, once started, the op process can
+// only read opBinary, tokenFile, and the dynamic libraries/files op needs at
+// runtime. An empty or disabled cfg leaves cmd untouched.
+func WrapCommand(cmd *exec.Cmd, cfg SandboxConfig, opBinary, tokenFile string) error {
+	if !cfg.Enable {
+		return nil
+	}
+
+	switch cfg.Backend {
+	case SandboxBackendBubblewrap, "":
+		return wrapWithBubblewrap(cmd, opBinary, tokenFile)
+	case SandboxBackendLandlock:
+		return wrapWithLandlock(cmd, opBinary, tokenFile)
+	default:
+		return fmt.Errorf("unknown sandbox backend %q: want %q or %q", cfg.Backend, SandboxBackendBubblewrap, SandboxBackendLandlock)
+	}
+}
+
+// wrapWithBubblewrap re-execs cmd under bwrap, bind-mounting a minimal
+// read-only rootfs, the op binary, and the token file, with no filesystem
+// access beyond that and network left up to --share-net.
+func wrapWithBubblewrap(cmd *exec.Cmd, opBinary, tokenFile string) error {
+	bwrapPath, err := exec.LookPath("bwrap")
+	if err != nil {
+		return fmt.Errorf("sandbox.backend=bwrap requested but bwrap is not on PATH: %w", err)
+	}
+
+	args := []string{
+		"--ro-bind", "/usr", "/usr",
+		"--ro-bind", "/lib", "/lib",
+		"--ro-bind", "/lib64", "/lib64",
+		"--ro-bind", "/etc/resolv.conf", "/etc/resolv.conf",
+		"--ro-bind", "/etc/ssl", "/etc/ssl",
+	}
+	if nixStorePath, ok := nixStoreBind(opBinary); ok {
+		args = append(args, "--ro-bind", nixStorePath, nixStorePath)
+	}
+	args = append(args,
+		"--ro-bind", opBinary, opBinary,
+		"--ro-bind", tokenFile, tokenFile,
+		"--dev", "/dev",
+		"--proc", "/proc",
+		"--unshare-all",
+		"--share-net",
+		"--die-with-parent",
+		opBinary,
+	)
+	args = append(args, cmd.Args[1:]...)
+
+	cmd.Path = bwrapPath
+	cmd.Args = append([]string{bwrapPath}, args...)
+	return nil
+}
+
+// nixStoreBind reports the /nix/store path to bind read-only into the
+// sandbox, if any. On NixOS (and any Nix-managed system), op itself is
+// installed under /nix/store and dynamically links against its runtime
+// dependencies - libc, the dynamic linker, libssl, etc. - which also live
+// under /nix/store, never under /usr or /lib. Binding the whole store is
+// coarser than resolving op's exact closure, but it's the only way to cover
+// those dependencies without shelling out to `nix-store -q --requisites`,
+// which isn't guaranteed to be on PATH either.
+func nixStoreBind(opBinary string) (string, bool) {
+	const nixStore = "/nix/store"
+	if !strings.HasPrefix(opBinary, nixStore+"/") {
+		return "", false
+	}
+	if _, err := os.Stat(nixStore); err != nil {
+		return "", false
+	}
+	return nixStore, true
+}
+
+// wrapWithLandlock does not restrict the calling opnix process - that
+// process is long-lived and keeps doing unrelated filesystem work
+// (rewriting outputDir, re-reading secrets.json on every watch tick, etc.)
+// after op has been spawned, and Landlock restrictions are per-thread,
+// inherited by children but impossible to safely scope to "just this one
+// exec" from a multi-goroutine host without also restricting the host
+// itself for the rest of its life. Instead, cmd is rewritten to re-exec the
+// opnix binary as a throwaway helper: that fresh child process applies the
+// Landlock ruleset to itself in RunLandlockHelper and then execs directly
+// into op, so the restriction lives and dies with that one subprocess.
+func wrapWithLandlock(cmd *exec.Cmd, opBinary, tokenFile string) error {
+	self, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("resolving own executable path for landlock helper re-exec: %w", err)
+	}
+
+	helperArgs := append([]string{landlockHelperArg, opBinary, tokenFile}, cmd.Args[1:]...)
+	cmd.Path = self
+	cmd.Args = append([]string{self}, helperArgs...)
+	return nil
+}
+
+// RunLandlockHelper is the entire body of the re-exec'd landlock helper
+// process: it builds a ruleset restricting file reads and execution to
+// opBinary, tokenFile and the runtime paths the bwrap backend also binds,
+// restricts itself to it, then execve()s into opBinary with
+// the remaining arguments. On success it never returns - the process image
+// is replaced by op, still carrying the restriction, which is exactly the
+// property wrapWithLandlock needs. args is os.Args[2:] of the helper
+// invocation: {opBinary, tokenFile, opArgs...}.
+func RunLandlockHelper(args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("landlock helper: expected at least opBinary and tokenFile, got %d args", len(args))
+	}
+	opBinary, tokenFile, opArgs := args[0], args[1], args[2:]
+
+	rulesetFD, err := landlockCreateRuleset(&landlockRulesetAttr{
+		HandledAccessFS: landlockAccessFSReadFile | landlockAccessFSExecute,
+	})
+	if err != nil {
+		return fmt.Errorf("creating landlock ruleset: %w", err)
+	}
+	// rulesetFD is deliberately left open: landlockRestrictSelf consumes it,
+	// and the process below either execs away (closing every fd) or returns
+	// an error and exits, so there's no long-lived leak to clean up.
+
+	// Besides op and its token, allow the same runtime paths the bwrap
+	// backend binds read-only, where they exist on this system.
+	paths := []string{opBinary, tokenFile}
+	for _, p := range []string{"/usr", "/lib", "/lib64", "/etc/resolv.conf", "/etc/ssl"} {
+		if _, err := os.Stat(p); err == nil {
+			paths = append(paths, p)
+		}
+	}
+	if nixStorePath, ok := nixStoreBind(opBinary); ok {
+		paths = append(paths, nixStorePath)
+	}
+
+	for _, path := range paths {
+		if err := addLandlockPathRule(rulesetFD, path, landlockAccessFSReadFile|landlockAccessFSExecute); err != nil {
+			return fmt.Errorf("adding landlock rule for %s: %w", path, err)
+		}
+	}
+
+	// Landlock restricts only the calling OS thread, not the whole process,
+	// and the Go scheduler is free to migrate this goroutine to a different
+	// thread at any preemption point - including inside exec.LookPath below -
+	// which would let op exec on a thread that was never restricted. Pin to
+	// the current thread for the rest of this process's life; no matching
+	// UnlockOSThread is needed since we either exec away below, replacing
+	// the process image entirely, or return an error that exits the process.
+	runtime.LockOSThread()
+
+	if err := landlockRestrictSelf(rulesetFD); err != nil {
+		return fmt.Errorf("restricting self via landlock: %w", err)
+	}
+
+	argv := append([]string{opBinary}, opArgs...)
+	return execInto(opBinary, argv)
+}
+
+// LandlockSupported reports whether the running kernel supports Landlock,
+// so validateSandboxPrerequisites can downgrade to a warning instead of failing
+// when sandbox.backend=landlock is requested on an older kernel.
+func LandlockSupported() bool {
+	return landlockCreateRulesetSupported()
+}
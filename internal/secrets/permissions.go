@@ -0,0 +1,145 @@
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+)
+
+// defaultSecretMode is applied when a secret entry doesn't request one
+// explicitly. Secrets are private-readable by their owner unless a service
+// needs broader access, which it must ask for explicitly.
+const defaultSecretMode = 0400
+
+// OwnerSpec is the resolved owner/group/mode for a single secret, parsed
+// from the optional "owner", "group" and "mode" fields on a secrets.json
+// entry.
+type OwnerSpec struct {
+	Owner string
+	Group string
+	Mode  string
+}
+
+// WriteSecretFile writes data to path with the owner/group/mode requested by
+// spec, atomically. Intended to be called from Processor.Process for every
+// secret entry in place of a plain os.WriteFile.
+func WriteSecretFile(path string, data []byte, spec OwnerSpec) error {
+	return writeSecretFile(path, data, spec)
+}
+
+// RequiresPrivilegedChown reports whether spec names an owner or group other
+// than the current process's own, i.e. applying it will need CAP_CHOWN.
+func RequiresPrivilegedChown(spec OwnerSpec) (bool, error) {
+	return spec.requiresPrivilegedChown()
+}
+
+// resolvedPermissions is OwnerSpec after name lookups, ready to apply to a
+// file.
+type resolvedPermissions struct {
+	UID  int
+	GID  int
+	Mode os.FileMode
+}
+
+// resolve looks up the configured owner/group names (falling back to the
+// current process's uid/gid when unset) and parses the octal mode string.
+func (s OwnerSpec) resolve() (resolvedPermissions, error) {
+	rp := resolvedPermissions{UID: os.Getuid(), GID: os.Getgid(), Mode: defaultSecretMode}
+
+	if s.Owner != "" {
+		u, err := user.Lookup(s.Owner)
+		if err != nil {
+			return rp, fmt.Errorf("looking up owner %q: %w", s.Owner, err)
+		}
+		uid, err := strconv.Atoi(u.Uid)
+		if err != nil {
+			return rp, fmt.Errorf("parsing uid for owner %q: %w", s.Owner, err)
+		}
+		rp.UID = uid
+	}
+
+	if s.Group != "" {
+		g, err := user.LookupGroup(s.Group)
+		if err != nil {
+			return rp, fmt.Errorf("looking up group %q: %w", s.Group, err)
+		}
+		gid, err := strconv.Atoi(g.Gid)
+		if err != nil {
+			return rp, fmt.Errorf("parsing gid for group %q: %w", s.Group, err)
+		}
+		rp.GID = gid
+	}
+
+	if s.Mode != "" {
+		mode, err := strconv.ParseUint(s.Mode, 8, 32)
+		if err != nil {
+			return rp, fmt.Errorf("parsing mode %q as octal: %w", s.Mode, err)
+		}
+		rp.Mode = os.FileMode(mode)
+	}
+
+	return rp, nil
+}
+
+// writeSecretFile writes data to path with the requested owner/group/mode,
+// never exposing a partial or over-permissive file to readers: the secret
+// is written to a sibling temp file, chmod'd and chown'd there, then
+// renamed into place atomically.
+func writeSecretFile(path string, data []byte, spec OwnerSpec) error {
+	rp, err := spec.resolve()
+	if err != nil {
+		return fmt.Errorf("resolving permissions for %s: %w", path, err)
+	}
+
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, ".opnix-secret-*")
+	if err != nil {
+		return fmt.Errorf("creating temp file in %s: %w", dir, err)
+	}
+	tmpPath := tmp.Name()
+
+	// Clean up the temp file on any failure path below; a successful
+	// rename leaves nothing at tmpPath to remove.
+	success := false
+	defer func() {
+		if !success {
+			_ = os.Remove(tmpPath)
+		}
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing %s: %w", tmpPath, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("closing %s: %w", tmpPath, err)
+	}
+
+	if err := os.Chmod(tmpPath, rp.Mode); err != nil {
+		return fmt.Errorf("chmod %s to %o: %w", tmpPath, rp.Mode, err)
+	}
+
+	if err := os.Chown(tmpPath, rp.UID, rp.GID); err != nil {
+		return fmt.Errorf("chown %s to %d:%d: %w", tmpPath, rp.UID, rp.GID, err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("renaming %s into place at %s: %w", tmpPath, path, err)
+	}
+
+	success = true
+	return nil
+}
+
+// requiresPrivilegedChown reports whether applying spec to a file owned by
+// the current process would need CAP_CHOWN, i.e. it names an owner or group
+// other than the process's own.
+func (s OwnerSpec) requiresPrivilegedChown() (bool, error) {
+	rp, err := s.resolve()
+	if err != nil {
+		return false, err
+	}
+	return rp.UID != os.Getuid() || rp.GID != os.Getgid(), nil
+}
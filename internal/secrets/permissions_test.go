@@ -0,0 +1,56 @@
+package secrets
+
+import (
+	"os"
+	"testing"
+)
+
+func TestOwnerSpecResolveDefaults(t *testing.T) {
+	spec := OwnerSpec{}
+	rp, err := spec.resolve()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rp.UID != os.Getuid() || rp.GID != os.Getgid() {
+		t.Fatalf("got uid/gid %d/%d, want current process's %d/%d", rp.UID, rp.GID, os.Getuid(), os.Getgid())
+	}
+	if rp.Mode != defaultSecretMode {
+		t.Fatalf("got mode %o, want default %o", rp.Mode, defaultSecretMode)
+	}
+}
+
+func TestOwnerSpecResolveMode(t *testing.T) {
+	spec := OwnerSpec{Mode: "0440"}
+	rp, err := spec.resolve()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rp.Mode != 0440 {
+		t.Fatalf("got mode %o, want 0440", rp.Mode)
+	}
+}
+
+func TestOwnerSpecResolveInvalidMode(t *testing.T) {
+	spec := OwnerSpec{Mode: "not-octal"}
+	if _, err := spec.resolve(); err == nil {
+		t.Fatal("expected error for non-octal mode")
+	}
+}
+
+func TestOwnerSpecResolveUnknownOwner(t *testing.T) {
+	spec := OwnerSpec{Owner: "opnix-test-user-that-does-not-exist"}
+	if _, err := spec.resolve(); err == nil {
+		t.Fatal("expected error for unknown owner")
+	}
+}
+
+func TestOwnerSpecRequiresPrivilegedChownSameUser(t *testing.T) {
+	spec := OwnerSpec{}
+	requires, err := spec.requiresPrivilegedChown()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requires {
+		t.Fatal("an empty OwnerSpec should never require CAP_CHOWN")
+	}
+}
@@ -0,0 +1,84 @@
+// Package secrets fetches configured secrets from 1Password and writes
+// them to disk.
+package secrets
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/brizzbuzz/opnix/internal/config"
+	"github.com/brizzbuzz/opnix/internal/onepass"
+)
+
+// Processor fetches every secret in a Config and writes it under outputDir.
+type Processor struct {
+	client    *onepass.Client
+	outputDir string
+}
+
+// NewProcessor returns a Processor that fetches secrets via client and
+// writes them under outputDir.
+func NewProcessor(client *onepass.Client, outputDir string) *Processor {
+	return &Processor{client: client, outputDir: outputDir}
+}
+
+// Result summarizes a Process run.
+type Result struct {
+	// ProcessedCount is the number of secrets actually (re)written this
+	// run, i.e. excluding ones whose fetched value matched what was
+	// already on disk.
+	ProcessedCount int
+	// SecretPaths maps every configured secret's name to the path it was
+	// written to (or would be written to), regardless of whether it
+	// changed this run.
+	SecretPaths map[string]string
+}
+
+// Process fetches every secret in cfg and writes any whose contents differ
+// from what's currently on disk, atomically and with the requested
+// owner/group/mode.
+func (p *Processor) Process(cfg *config.Config) (*Result, error) {
+	result := &Result{SecretPaths: make(map[string]string, len(cfg.Secrets))}
+
+	for _, secret := range cfg.Secrets {
+		path := secret.Path
+		if path == "" {
+			path = filepath.Join(p.outputDir, secret.Name)
+		}
+		result.SecretPaths[secret.Name] = path
+
+		data, err := p.fetch(secret)
+		if err != nil {
+			return nil, fmt.Errorf("fetching secret %q: %w", secret.Name, err)
+		}
+
+		if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, data) {
+			continue
+		}
+
+		spec := OwnerSpec{Owner: secret.Owner, Group: secret.Group, Mode: secret.Mode}
+		if err := WriteSecretFile(path, data, spec); err != nil {
+			return nil, fmt.Errorf("writing secret %q to %s: %w", secret.Name, path, err)
+		}
+
+		result.ProcessedCount++
+	}
+
+	return result, nil
+}
+
+// fetch resolves a single secret entry to its current bytes: a direct
+// 1Password reference, or a rendered template for type: "template" entries.
+func (p *Processor) fetch(secret config.Secret) ([]byte, error) {
+	if secret.Type == "template" {
+		vars, err := FetchTemplateVars(p.client, secret.Variables)
+		if err != nil {
+			return nil, err
+		}
+		return RenderTemplate(secret.Name, secret.Source, vars)
+	}
+
+	return p.client.FetchSecret(secret.Reference)
+}
@@ -0,0 +1,80 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/brizzbuzz/opnix/internal/onepass"
+)
+
+// TemplateVars is passed to a template entry as the top-level ".Var", mapping
+// each name in the entry's "variables" map to the fetched 1Password value.
+type TemplateVars map[string]string
+
+// templateFuncs are available to every rendered secret template, covering
+// the composite-file cases (pgpass, .env, JSON/YAML configs) that motivated
+// adding template entries in the first place.
+var templateFuncs = template.FuncMap{
+	"b64enc": func(s string) string {
+		return base64.StdEncoding.EncodeToString([]byte(s))
+	},
+	"quote": strconv.Quote,
+	"indent": func(spaces int, s string) string {
+		pad := strings.Repeat(" ", spaces)
+		lines := strings.Split(s, "\n")
+		for i, line := range lines {
+			lines[i] = pad + line
+		}
+		return strings.Join(lines, "\n")
+	},
+	"toJson": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	},
+	"toYaml": marshalYAML,
+}
+
+// FetchTemplateVars resolves every variable in a template entry's
+// "variables" map (each a 1Password reference) to its current value.
+func FetchTemplateVars(client *onepass.Client, variables map[string]string) (TemplateVars, error) {
+	vars := make(TemplateVars, len(variables))
+	for name, ref := range variables {
+		value, err := client.FetchSecret(ref)
+		if err != nil {
+			return nil, fmt.Errorf("fetching template variable %q: %w", name, err)
+		}
+		vars[name] = string(value)
+	}
+	return vars, nil
+}
+
+// RenderTemplate renders a template entry's source (inline text, or the
+// contents of source if it names an existing file) against the fetched
+// variables and returns the rendered bytes.
+func RenderTemplate(name, source string, vars TemplateVars) ([]byte, error) {
+	body := source
+	if data, err := os.ReadFile(source); err == nil {
+		body = string(data)
+	}
+
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(body)
+	if err != nil {
+		return nil, fmt.Errorf("parsing template for %q: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, struct{ Var TemplateVars }{Var: vars}); err != nil {
+		return nil, fmt.Errorf("rendering template for %q: %w", name, err)
+	}
+
+	return buf.Bytes(), nil
+}
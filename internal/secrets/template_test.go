@@ -0,0 +1,49 @@
+package secrets
+
+import (
+	"os"
+	"testing"
+)
+
+func TestRenderTemplateInline(t *testing.T) {
+	vars := TemplateVars{"password": "hunter2"}
+	out, err := RenderTemplate("db-env", "DB_PASSWORD={{ .Var.password }}", vars)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := string(out), "DB_PASSWORD=hunter2"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestRenderTemplateFuncs(t *testing.T) {
+	vars := TemplateVars{"password": "hunter2"}
+	out, err := RenderTemplate("db-env", `PASSWORD_B64={{ b64enc .Var.password }}`, vars)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := string(out), "PASSWORD_B64=aHVudGVyMg=="; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestRenderTemplateFromFile(t *testing.T) {
+	path := t.TempDir() + "/template.tmpl"
+	if err := os.WriteFile(path, []byte("hello {{ .Var.name }}"), 0600); err != nil {
+		t.Fatalf("writing fixture template: %v", err)
+	}
+
+	out, err := RenderTemplate("greeting", path, TemplateVars{"name": "world"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := string(out), "hello world"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestRenderTemplateParseError(t *testing.T) {
+	if _, err := RenderTemplate("bad", "{{ .Var.missing ", TemplateVars{}); err == nil {
+		t.Fatal("expected parse error for malformed template")
+	}
+}
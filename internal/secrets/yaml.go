@@ -0,0 +1,113 @@
+package secrets
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// marshalYAML renders v as YAML for the toYaml template func. opnix avoids
+// taking a dependency on gopkg.in/yaml.v3 for this one use (embedding a
+// handful of fetched 1Password values into a config file); it supports the
+// shapes that actually show up there - maps, slices, strings, numbers,
+// bools and nil - rather than the full YAML data model.
+func marshalYAML(v interface{}) (string, error) {
+	var b strings.Builder
+	if err := writeYAML(&b, v, 0); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func writeYAML(b *strings.Builder, v interface{}, indent int) error {
+	rv := reflect.ValueOf(v)
+	switch {
+	case v == nil:
+		b.WriteString("null\n")
+		return nil
+	case rv.Kind() == reflect.Map:
+		return writeYAMLMap(b, rv, indent)
+	case rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array:
+		return writeYAMLSlice(b, rv, indent)
+	default:
+		b.WriteString(yamlScalar(v))
+		b.WriteString("\n")
+		return nil
+	}
+}
+
+func writeYAMLMap(b *strings.Builder, rv reflect.Value, indent int) error {
+	if rv.Len() == 0 {
+		b.WriteString("{}\n")
+		return nil
+	}
+
+	keys := make([]string, 0, rv.Len())
+	for _, k := range rv.MapKeys() {
+		keys = append(keys, fmt.Sprintf("%v", k.Interface()))
+	}
+	sort.Strings(keys)
+
+	if indent == 0 {
+		b.WriteString("\n")
+	}
+
+	pad := strings.Repeat("  ", indent)
+	for _, k := range keys {
+		val := rv.MapIndex(reflect.ValueOf(k).Convert(rv.Type().Key())).Interface()
+		b.WriteString(pad)
+		b.WriteString(k)
+		b.WriteString(":")
+		if isYAMLScalar(val) {
+			b.WriteString(" ")
+			b.WriteString(yamlScalar(val))
+			b.WriteString("\n")
+		} else {
+			b.WriteString("\n")
+			if err := writeYAML(b, val, indent+1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func writeYAMLSlice(b *strings.Builder, rv reflect.Value, indent int) error {
+	if rv.Len() == 0 {
+		b.WriteString("[]\n")
+		return nil
+	}
+
+	pad := strings.Repeat("  ", indent)
+	for i := 0; i < rv.Len(); i++ {
+		val := rv.Index(i).Interface()
+		b.WriteString(pad)
+		b.WriteString("- ")
+		b.WriteString(yamlScalar(val))
+		b.WriteString("\n")
+	}
+	return nil
+}
+
+func isYAMLScalar(v interface{}) bool {
+	k := reflect.ValueOf(v).Kind()
+	return k != reflect.Map && k != reflect.Slice && k != reflect.Array
+}
+
+func yamlScalar(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		if val == "" || strings.ContainsAny(val, ":#\n\"'") {
+			return strconv.Quote(val)
+		}
+		return val
+	case bool, int, int64, float64:
+		return fmt.Sprintf("%v", val)
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
@@ -0,0 +1,45 @@
+// Package systemd restarts the configured systemd services after secrets
+// are written.
+package systemd
+
+import (
+	"fmt"
+	"os/exec"
+
+	"github.com/brizzbuzz/opnix/internal/config"
+)
+
+// Manager dispatches systemctl try-restart for the services listed in
+// the "systemdIntegration" block of secrets.json.
+type Manager struct {
+	cfg config.SystemdIntegration
+}
+
+// NewManager validates that systemctl is available and returns a Manager
+// for cfg.
+func NewManager(cfg config.SystemdIntegration) (*Manager, error) {
+	if _, err := exec.LookPath("systemctl"); err != nil {
+		return nil, fmt.Errorf("locating systemctl on PATH: %w", err)
+	}
+	return &Manager{cfg: cfg}, nil
+}
+
+// ProcessSecretChanges restarts every configured service whenever
+// secretPaths is non-empty. secretPaths is only checked for emptiness:
+// which secrets it names, and whether they changed this run, is not
+// consulted. Services are restarted unconditionally rather than mapped
+// per-secret, since a single unit commonly consumes several secrets at once.
+func (m *Manager) ProcessSecretChanges(secrets []config.Secret, secretPaths map[string]string) error {
+	if len(secretPaths) == 0 || !m.cfg.Enable {
+		return nil
+	}
+
+	for _, service := range m.cfg.Services {
+		cmd := exec.Command("systemctl", "try-restart", service)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("restarting %s: %w: %s", service, err, out)
+		}
+	}
+
+	return nil
+}
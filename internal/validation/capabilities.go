@@ -0,0 +1,61 @@
+package validation
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// capChown is CAP_CHOWN's bit position, per capability(7).
+const capChown = 0
+
+// HasChownCapability reports whether the running process carries CAP_CHOWN
+// in its effective capability set, by reading the CapEff bitmask from
+// /proc/self/status. It's used to fail fast, before any secrets are
+// written, when a secret requests an owner/group other than the current
+// user's.
+func HasChownCapability() (bool, error) {
+	if os.Geteuid() == 0 {
+		// Root carries every capability regardless of CapEff bookkeeping
+		// (e.g. when capability sets have been cleared by a container
+		// runtime but DAC checks are still bypassed for uid 0).
+		return true, nil
+	}
+
+	f, err := os.Open("/proc/self/status")
+	if err != nil {
+		return false, fmt.Errorf("reading /proc/self/status: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "CapEff:") {
+			continue
+		}
+		return parseCapEffLine(line)
+	}
+
+	return false, fmt.Errorf("CapEff line not found in /proc/self/status")
+}
+
+// parseCapEffLine parses a "CapEff:\t<hex mask>" line from /proc/self/status
+// and reports whether CAP_CHOWN is set in it, split out from
+// HasChownCapability so the bitmask parsing can be tested without a real
+// /proc/self/status.
+func parseCapEffLine(line string) (bool, error) {
+	fields := strings.Fields(line)
+	if len(fields) != 2 {
+		return false, fmt.Errorf("unexpected CapEff line format: %q", line)
+	}
+
+	mask, err := strconv.ParseUint(fields[1], 16, 64)
+	if err != nil {
+		return false, fmt.Errorf("parsing CapEff mask %q: %w", fields[1], err)
+	}
+
+	return mask&(1<<capChown) != 0, nil
+}
@@ -0,0 +1,56 @@
+package validation
+
+import "testing"
+
+func TestParseCapEffLine(t *testing.T) {
+	cases := []struct {
+		name    string
+		line    string
+		want    bool
+		wantErr bool
+	}{
+		{
+			name: "chown bit set",
+			line: "CapEff:\t0000000000000001",
+			want: true,
+		},
+		{
+			name: "chown bit unset",
+			line: "CapEff:\t0000000000000002",
+			want: false,
+		},
+		{
+			name: "full capability set",
+			line: "CapEff:\t0000003fffffffff",
+			want: true,
+		},
+		{
+			name:    "malformed line",
+			line:    "CapEff:",
+			wantErr: true,
+		},
+		{
+			name:    "non-hex mask",
+			line:    "CapEff:\tnotahexvalue",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseCapEffLine(tc.line)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
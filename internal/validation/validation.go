@@ -0,0 +1,32 @@
+// Package validation holds pre-flight checks run before opnix touches any
+// secrets.
+package validation
+
+import (
+	"fmt"
+	"os"
+)
+
+// Validator groups the pre-flight checks secretCommand runs before
+// processing secrets.
+type Validator struct{}
+
+// NewValidator returns a Validator ready to use.
+func NewValidator() *Validator {
+	return &Validator{}
+}
+
+// ValidateTokenFile checks that the 1Password service account token file
+// exists and is non-empty. It does not fail the caller's run - some call
+// sites continue with a warning so that a previously-fetched secrets
+// directory remains usable even if the token has since been removed.
+func (v *Validator) ValidateTokenFile(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("token file %s: %w", path, err)
+	}
+	if info.Size() == 0 {
+		return fmt.Errorf("token file %s is empty", path)
+	}
+	return nil
+}